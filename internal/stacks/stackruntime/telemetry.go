@@ -0,0 +1,54 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package stackruntime
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracerProviderContextKey struct{}
+
+// ContextWithTracerProvider returns a new context derived from ctx that
+// carries the given TracerProvider, to be retrieved later by
+// tracerProviderFromContext.
+//
+// Callers of the plan/apply/validate evaluation entry points can use this
+// to route the spans that stackruntime's internal promise/async-task
+// bookkeeping produces into their own tracing pipeline, instead of always
+// going to whatever happens to be installed as the process-wide global
+// TracerProvider. This mirrors the OTel convention of obtaining a
+// TracerProvider from configuration rather than from a global.
+//
+// This package doesn't yet define those plan/apply/validate entry points
+// itself (they live further up the stackruntime tree, outside what's
+// checked in here), so wiring an option onto them that calls this is
+// left to that layer; every internal Tracer lookup that already exists in
+// this package -- namely tracer, below -- goes through it.
+func ContextWithTracerProvider(ctx context.Context, tp trace.TracerProvider) context.Context {
+	return context.WithValue(ctx, tracerProviderContextKey{}, tp)
+}
+
+// tracerProviderFromContext returns the TracerProvider previously stored in
+// ctx by ContextWithTracerProvider, or the global default TracerProvider if
+// ctx doesn't have one.
+func tracerProviderFromContext(ctx context.Context) trace.TracerProvider {
+	if tp, ok := ctx.Value(tracerProviderContextKey{}).(trace.TracerProvider); ok {
+		return tp
+	}
+	return otel.GetTracerProvider()
+}
+
+// tracer returns the Tracer that internal stackruntime code should use to
+// start new spans for the operation associated with ctx.
+//
+// Internal call sites should use this instead of calling otel.Tracer
+// directly, so that a TracerProvider configured through
+// ContextWithTracerProvider is honored consistently throughout a single
+// plan/apply/validate evaluation.
+func tracer(ctx context.Context, name string) trace.Tracer {
+	return tracerProviderFromContext(ctx).Tracer(name)
+}
@@ -0,0 +1,43 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package stackruntime
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/hashicorp/terraform/internal/stacks/stackruntime/tracetest"
+)
+
+// TestTracerHonorsContextProvider verifies that tracer, the helper internal
+// code should use in place of otel.Tracer, picks up whatever TracerProvider
+// was attached to ctx by ContextWithTracerProvider, rather than always
+// falling back to the process-wide global.
+func TestTracerHonorsContextProvider(t *testing.T) {
+	recorder := tracetest.NewRecorder()
+	ctx := ContextWithTracerProvider(context.Background(), recorder)
+
+	_, span := tracer(ctx, "stackruntime").Start(ctx, "async task")
+	span.End()
+
+	spans := recorder.ByName("async task")
+	if len(spans) != 1 {
+		t.Fatalf("got %d \"async task\" spans recorded, want 1", len(spans))
+	}
+	if !spans[0].Ended {
+		t.Errorf("recorded span was never marked as ended")
+	}
+}
+
+// TestTracerFallsBackToGlobalProvider verifies that tracer still falls back
+// to the process-wide global TracerProvider when ctx doesn't carry one, so
+// that code paths that haven't been threaded through
+// ContextWithTracerProvider yet keep behaving as before.
+func TestTracerFallsBackToGlobalProvider(t *testing.T) {
+	if got, want := tracerProviderFromContext(context.Background()), otel.GetTracerProvider(); got != want {
+		t.Errorf("tracerProviderFromContext(context.Background()) = %v, want the global TracerProvider %v", got, want)
+	}
+}
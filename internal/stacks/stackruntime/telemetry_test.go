@@ -13,45 +13,38 @@ import (
 	"sync"
 	"testing"
 
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"go.opentelemetry.io/otel/trace/embedded"
 )
 
-// tracesToTestLog arranges for any traces generated by the current test to
-// be emitted directly into the test log using the log methods of the given
-// [testing.T].
+// tracesToTestLog returns a TracerProvider that arranges for any traces
+// created through it to be emitted directly into the test log using the
+// log methods of the given [testing.T].
 //
-// This works by temporarily reassigning the global tracer provider and so
-// is not suitable for parallel tests or subtests of tests that have already
-// called this function.
+// The caller should pass the result to ContextWithTracerProvider and use
+// the resulting context for the plan/apply/validate evaluation under test,
+// e.g.:
+//
+//	ctx := ContextWithTracerProvider(context.Background(), tracesToTestLog(t))
+//
+// Because this no longer reassigns the global tracer provider, it's safe
+// to use from parallel tests and from multiple subtests of the same test.
 //
 // The results of this function are pretty chatty, so we should typically not
 // leave this in a test checked in to version control, but it can be helpful to
 // add temporarily during test debugging if it's unclear exactly how different
 // components are interacting with one another.
-func tracesToTestLog(t *testing.T) {
+func tracesToTestLog(t *testing.T) *testLogTracerProvider {
 	t.Helper()
-	oldProvider := otel.GetTracerProvider()
-	if _, ok := oldProvider.(*testLogTracerProvider); ok {
-		// This suggests that someone's tried to use tracesToTestLog in
-		// a parallel test or in a subtest of a test that already called it.
-		t.Fatal("overlapping tracesToTestLog")
-	}
-	t.Cleanup(func() {
-		otel.SetTracerProvider(oldProvider)
-	})
-
-	provider := testLogTracerProvider{
+	return &testLogTracerProvider{
 		t: t,
 		spanTracker: &spanTracker{
 			names:  make(map[trace.SpanID]string),
 			nextID: 1,
 		},
 	}
-	otel.SetTracerProvider(provider)
 }
 
 type testLogTracerProvider struct {
@@ -132,7 +125,7 @@ func (sn *spanTracker) SpanAttrDisplay(kv attribute.KeyValue) string {
 var _ trace.TracerProvider = (*testLogTracerProvider)(nil)
 
 // Tracer implements trace.TracerProvider.
-func (p testLogTracerProvider) Tracer(name string, options ...trace.TracerOption) trace.Tracer {
+func (p *testLogTracerProvider) Tracer(name string, options ...trace.TracerOption) trace.Tracer {
 	p.t.Helper()
 	return &testLogTracer{
 		t:           p.t,
@@ -210,12 +203,15 @@ type testLogTraceSpan struct {
 	t           *testing.T
 	spanTracker *spanTracker
 
+	mu    sync.Mutex
+	attrs []attribute.KeyValue
+
 	embedded.Span
 }
 
 var _ trace.Span = (*testLogTraceSpan)(nil)
 
-func (s testLogTraceSpan) log(f string, args ...any) {
+func (s *testLogTraceSpan) log(f string, args ...any) {
 	s.t.Helper()
 	s.t.Logf(
 		"[trace:%s] %s\n%s",
@@ -232,7 +228,7 @@ func testingSpanIDString(id trace.SpanID) string {
 }
 
 // AddEvent implements trace.Span.
-func (s testLogTraceSpan) AddEvent(name string, options ...trace.EventOption) {
+func (s *testLogTraceSpan) AddEvent(name string, options ...trace.EventOption) {
 	s.t.Helper()
 	cfg := trace.NewEventConfig(options...)
 	var attrsBuilder strings.Builder
@@ -243,26 +239,44 @@ func (s testLogTraceSpan) AddEvent(name string, options ...trace.EventOption) {
 }
 
 // End implements trace.Span.
-func (s testLogTraceSpan) End(options ...trace.SpanEndOption) {
+func (s *testLogTraceSpan) End(options ...trace.SpanEndOption) {
 	s.t.Helper()
-	s.log("END")
+	s.mu.Lock()
+	attrs := s.attrs
+	s.mu.Unlock()
+
+	var attrsBuilder strings.Builder
+	for _, kv := range attrs {
+		fmt.Fprintf(&attrsBuilder, "\n%s = %s", kv.Key, s.spanTracker.SpanAttrDisplay(kv))
+	}
+	s.log("END%s", attrsBuilder.String())
 }
 
 // IsRecording implements trace.Span.
-func (s testLogTraceSpan) IsRecording() bool {
+func (s *testLogTraceSpan) IsRecording() bool {
 	s.t.Helper()
 	return true
 }
 
 // RecordError implements trace.Span.
-func (s testLogTraceSpan) RecordError(err error, options ...trace.EventOption) {
+func (s *testLogTraceSpan) RecordError(err error, options ...trace.EventOption) {
 	s.t.Helper()
 	s.log("ERROR %s", err)
 }
 
 // SetAttributes implements trace.Span.
-func (s testLogTraceSpan) SetAttributes(kv ...attribute.KeyValue) {
+//
+// Unlike Start's attributes, which are logged immediately, attributes set
+// here are only accumulated: they're logged together, in the order they
+// were added, when the span ends. That's so that the full attribute set
+// for a span appears in one place even when (as the promising package
+// often does) some attributes are only known after the span has already
+// started.
+func (s *testLogTraceSpan) SetAttributes(kv ...attribute.KeyValue) {
 	s.t.Helper()
+	s.mu.Lock()
+	s.attrs = append(s.attrs, kv...)
+	s.mu.Unlock()
 }
 
 // SetName implements trace.Span.
@@ -273,27 +287,40 @@ func (s *testLogTraceSpan) SetName(name string) {
 }
 
 // SetStatus implements trace.Span.
-func (s testLogTraceSpan) SetStatus(code codes.Code, description string) {
+func (s *testLogTraceSpan) SetStatus(code codes.Code, description string) {
 	s.t.Helper()
 	s.log("STATUS %s: %s", code, description)
 }
 
 // SpanContext implements trace.Span.
-func (s testLogTraceSpan) SpanContext() trace.SpanContext {
+func (s *testLogTraceSpan) SpanContext() trace.SpanContext {
 	s.t.Helper()
 	return *s.context
 }
 
 // TracerProvider implements trace.Span.
-func (s testLogTraceSpan) TracerProvider() trace.TracerProvider {
+func (s *testLogTraceSpan) TracerProvider() trace.TracerProvider {
 	s.t.Helper()
-	return testLogTracerProvider{
+	return &testLogTracerProvider{
 		t:           s.t,
 		spanTracker: s.spanTracker,
 	}
 }
 
 // AddLink implements trace.Span.
-func (s testLogTraceSpan) AddLink(link trace.Link) {
-	// Noop
+//
+// Links added here (as opposed to at Start, via trace.WithLinks) are
+// logged as a separate "LINK" line against this span as soon as they're
+// added, since the promising package often only learns about a delegation
+// edge after the span it concerns has already started.
+//
+// NOTE: the promising package itself isn't part of this checkout, so the
+// audit this was meant to prompt -- confirming every post-Start
+// delegation there actually calls AddLink/SetAttributes rather than
+// relying on Start's options -- hasn't happened yet. Only the tracer-side
+// half of this change (making AddLink/SetAttributes do something once
+// called) is done here; the promising-package audit is still open.
+func (s *testLogTraceSpan) AddLink(link trace.Link) {
+	s.t.Helper()
+	s.log("LINK: %s", s.spanTracker.SpanDisplay(link.SpanContext.SpanID()))
 }
@@ -0,0 +1,263 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package tracetest provides an in-memory OpenTelemetry TracerProvider that
+// records every span it starts, so that stackruntime's tests can make
+// structured assertions about the shape of the promise/async-task graph a
+// test produced instead of grepping through log output.
+//
+// It's conceptually similar to the OpenTelemetry SDK's
+// go.opentelemetry.io/otel/sdk/trace/tracetest.SpanRecorder, but retains
+// the parent/child ordering and the trace.Link calls that stackruntime's
+// promising package uses to encode delegation edges between promise spans,
+// neither of which the SDK recorder preserves in a form convenient for
+// this package's tests.
+package tracetest
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+)
+
+// Recorder is a trace.TracerProvider whose Tracers record every span they
+// start into an in-memory, goroutine-safe list.
+type Recorder struct {
+	mu     sync.Mutex
+	spans  []*RecordedSpan
+	nextID uint64
+
+	embedded.TracerProvider
+}
+
+var _ trace.TracerProvider = (*Recorder)(nil)
+
+// NewRecorder returns a new Recorder with no spans recorded yet.
+func NewRecorder() *Recorder {
+	return &Recorder{nextID: 1}
+}
+
+// Tracer implements trace.TracerProvider.
+func (r *Recorder) Tracer(name string, options ...trace.TracerOption) trace.Tracer {
+	return &recorderTracer{recorder: r}
+}
+
+// Spans returns every span recorded so far, in the order their Start was
+// called. The result includes spans that haven't ended yet; check
+// [RecordedSpan.Ended] if that matters to the caller.
+func (r *Recorder) Spans() []*RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ret := make([]*RecordedSpan, len(r.spans))
+	copy(ret, r.spans)
+	return ret
+}
+
+// ByName returns every recorded span with the given name, in the order
+// they were started.
+func (r *Recorder) ByName(name string) []*RecordedSpan {
+	var ret []*RecordedSpan
+	for _, s := range r.Spans() {
+		if s.Name == name {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+func (r *Recorder) record(s *RecordedSpan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, s)
+}
+
+// newSpanID returns a small, deterministic, incrementing span ID, similar
+// in spirit to the one stackruntime's testLogTracer generates: good enough
+// to be unique within a single recorder and to sort in start order, but
+// not a real random trace ID.
+func (r *Recorder) newSpanID() trace.SpanID {
+	r.mu.Lock()
+	id := r.nextID
+	r.nextID++
+	r.mu.Unlock()
+
+	var ret trace.SpanID
+	for i := range ret {
+		ret[len(ret)-1-i] = byte(id >> (8 * i))
+	}
+	return ret
+}
+
+var recorderTraceID = trace.TraceID{0xfe, 0xed, 0xfa, 0xce}
+
+type recorderTracer struct {
+	recorder *Recorder
+
+	embedded.Tracer
+}
+
+var _ trace.Tracer = (*recorderTracer)(nil)
+
+// Start implements trace.Tracer.
+func (t *recorderTracer) Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+	parentSpanCtx := trace.SpanContextFromContext(ctx)
+
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: recorderTraceID,
+		SpanID:  t.recorder.newSpanID(),
+	})
+
+	recorded := &RecordedSpan{
+		Name:         spanName,
+		context:      spanCtx,
+		ParentSpanID: parentSpanCtx.SpanID(),
+		Attributes:   append([]attribute.KeyValue(nil), cfg.Attributes()...),
+		Links:        append([]trace.Link(nil), cfg.Links()...),
+	}
+	t.recorder.record(recorded)
+
+	span := &recorderSpan{recorded: recorded, provider: t.recorder}
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+// RecordedSpan is a single span captured by a Recorder. It retains enough
+// detail to assert on the promise/async-task graph that stackruntime's
+// tracing produces: the span's name, its parent, any links it was started
+// or later augmented with, its attributes, events, status, and any
+// recorded errors.
+type RecordedSpan struct {
+	Name         string
+	ParentSpanID trace.SpanID
+	Events       []RecordedEvent
+	StatusCode   codes.Code
+	StatusDesc   string
+	Errors       []error
+	Ended        bool
+
+	context    trace.SpanContext
+	mu         sync.Mutex
+	Attributes []attribute.KeyValue
+	Links      []trace.Link
+}
+
+// RecordedEvent is a single AddEvent call captured against a RecordedSpan.
+type RecordedEvent struct {
+	Name       string
+	Attributes []attribute.KeyValue
+}
+
+// SpanContext returns the trace.SpanContext that the Recorder assigned to
+// this span when it started.
+func (s *RecordedSpan) SpanContext() trace.SpanContext {
+	return s.context
+}
+
+// HasAttr returns true if this span has an attribute with the given key
+// whose value, compared via its untyped Go representation, equals want.
+func (s *RecordedSpan) HasAttr(key attribute.Key, want any) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, kv := range s.Attributes {
+		if kv.Key == key && kv.Value.AsInterface() == want {
+			return true
+		}
+	}
+	return false
+}
+
+// HasLinkTo returns true if this span has a link pointing at other's span
+// ID, which is how stackruntime's promising package encodes a delegation
+// edge between two promise spans.
+func (s *RecordedSpan) HasLinkTo(other *RecordedSpan) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, link := range s.Links {
+		if link.SpanContext.SpanID() == other.context.SpanID() {
+			return true
+		}
+	}
+	return false
+}
+
+type recorderSpan struct {
+	recorded *RecordedSpan
+	provider *Recorder
+
+	embedded.Span
+}
+
+var _ trace.Span = (*recorderSpan)(nil)
+
+// End implements trace.Span.
+func (s *recorderSpan) End(options ...trace.SpanEndOption) {
+	s.recorded.mu.Lock()
+	defer s.recorded.mu.Unlock()
+	s.recorded.Ended = true
+}
+
+// AddEvent implements trace.Span.
+func (s *recorderSpan) AddEvent(name string, options ...trace.EventOption) {
+	cfg := trace.NewEventConfig(options...)
+	s.recorded.mu.Lock()
+	defer s.recorded.mu.Unlock()
+	s.recorded.Events = append(s.recorded.Events, RecordedEvent{
+		Name:       name,
+		Attributes: cfg.Attributes(),
+	})
+}
+
+// IsRecording implements trace.Span.
+func (s *recorderSpan) IsRecording() bool {
+	return true
+}
+
+// RecordError implements trace.Span.
+func (s *recorderSpan) RecordError(err error, options ...trace.EventOption) {
+	s.recorded.mu.Lock()
+	defer s.recorded.mu.Unlock()
+	s.recorded.Errors = append(s.recorded.Errors, err)
+}
+
+// SetAttributes implements trace.Span.
+func (s *recorderSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.recorded.mu.Lock()
+	defer s.recorded.mu.Unlock()
+	s.recorded.Attributes = append(s.recorded.Attributes, kv...)
+}
+
+// SetName implements trace.Span.
+func (s *recorderSpan) SetName(name string) {
+	s.recorded.mu.Lock()
+	defer s.recorded.mu.Unlock()
+	s.recorded.Name = name
+}
+
+// SetStatus implements trace.Span.
+func (s *recorderSpan) SetStatus(code codes.Code, description string) {
+	s.recorded.mu.Lock()
+	defer s.recorded.mu.Unlock()
+	s.recorded.StatusCode = code
+	s.recorded.StatusDesc = description
+}
+
+// SpanContext implements trace.Span.
+func (s *recorderSpan) SpanContext() trace.SpanContext {
+	return s.recorded.context
+}
+
+// TracerProvider implements trace.Span.
+func (s *recorderSpan) TracerProvider() trace.TracerProvider {
+	return s.provider
+}
+
+// AddLink implements trace.Span.
+func (s *recorderSpan) AddLink(link trace.Link) {
+	s.recorded.mu.Lock()
+	defer s.recorded.mu.Unlock()
+	s.recorded.Links = append(s.recorded.Links, link)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package tracetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRecorderSpansAndByName(t *testing.T) {
+	r := NewRecorder()
+	tracer := r.Tracer("test")
+
+	_, parent := tracer.Start(context.Background(), "async task")
+	ctx := trace.ContextWithSpan(context.Background(), parent)
+	_, child := tracer.Start(ctx, "promise")
+	child.End()
+	parent.End()
+
+	if got, want := len(r.Spans()), 2; got != want {
+		t.Fatalf("Spans() returned %d spans, want %d", got, want)
+	}
+
+	asyncTasks := r.ByName("async task")
+	if got, want := len(asyncTasks), 1; got != want {
+		t.Fatalf("ByName(\"async task\") returned %d spans, want %d", got, want)
+	}
+	if !asyncTasks[0].Ended {
+		t.Errorf("\"async task\" span not marked as ended")
+	}
+
+	promises := r.ByName("promise")
+	if got, want := len(promises), 1; got != want {
+		t.Fatalf("ByName(\"promise\") returned %d spans, want %d", got, want)
+	}
+	if got, want := promises[0].ParentSpanID, asyncTasks[0].SpanContext().SpanID(); got != want {
+		t.Errorf("\"promise\" span has parent %s, want %s", got, want)
+	}
+}
+
+func TestRecordedSpanHasAttr(t *testing.T) {
+	r := NewRecorder()
+	tracer := r.Tracer("test")
+
+	_, span := tracer.Start(context.Background(), "promise")
+	span.SetAttributes(attribute.String("promise.waiter_id", "abc"))
+	span.End()
+
+	recorded := r.ByName("promise")[0]
+	if !recorded.HasAttr(attribute.Key("promise.waiter_id"), "abc") {
+		t.Errorf("expected span to have promise.waiter_id = %q", "abc")
+	}
+	if recorded.HasAttr(attribute.Key("promise.waiter_id"), "def") {
+		t.Errorf("expected span not to have promise.waiter_id = %q", "def")
+	}
+	if recorded.HasAttr(attribute.Key("nonexistent"), "abc") {
+		t.Errorf("expected span not to have an attribute it was never given")
+	}
+}
+
+func TestRecordedSpanHasLinkTo(t *testing.T) {
+	r := NewRecorder()
+	tracer := r.Tracer("test")
+
+	_, resolver := tracer.Start(context.Background(), "promise")
+	_, waiter := tracer.Start(context.Background(), "promise")
+	waiter.AddLink(trace.Link{SpanContext: resolver.SpanContext()})
+	resolver.End()
+	waiter.End()
+
+	spans := r.ByName("promise")
+	resolverRecorded, waiterRecorded := spans[0], spans[1]
+	if !waiterRecorded.HasLinkTo(resolverRecorded) {
+		t.Errorf("expected waiter span to have a link to the resolver span")
+	}
+	if waiterRecorded.HasLinkTo(waiterRecorded) {
+		t.Errorf("expected waiter span not to have a link to itself")
+	}
+}
+
+func TestRecordedSpanEventsAndErrors(t *testing.T) {
+	r := NewRecorder()
+	tracer := r.Tracer("test")
+
+	_, span := tracer.Start(context.Background(), "async task")
+	span.AddEvent("scheduled")
+	span.RecordError(errors.New("boom"))
+	span.End()
+
+	recorded := r.ByName("async task")[0]
+	if got, want := len(recorded.Events), 1; got != want {
+		t.Fatalf("got %d events, want %d", got, want)
+	}
+	if got, want := recorded.Events[0].Name, "scheduled"; got != want {
+		t.Errorf("event name = %q, want %q", got, want)
+	}
+	if got, want := len(recorded.Errors), 1; got != want {
+		t.Fatalf("got %d errors, want %d", got, want)
+	}
+	if got, want := recorded.Errors[0].Error(), "boom"; got != want {
+		t.Errorf("error = %q, want %q", got, want)
+	}
+}
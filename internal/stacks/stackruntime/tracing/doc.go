@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package tracing constructs the production OpenTelemetry TracerProvider
+// that stackruntime uses to emit traces of a real plan, apply, or validate
+// operation, so that Terraform CLI users and Terraform Cloud/Enterprise
+// operators can collect structured traces of the promise scheduler from a
+// real run rather than only from the test-only log tracer in the parent
+// package.
+//
+// The resulting TracerProvider is meant to be passed to
+// stackruntime.ContextWithTracerProvider alongside the context used for a
+// plan/apply/validate evaluation. Wiring that up, and calling
+// ContextWithIncrementedPromiseDepth from the promising package as it
+// starts nested "promise" spans, are both left to those call sites --
+// neither exists in this checkout yet -- so for now this package is
+// exercised only by its own tests.
+//
+// # Configuration
+//
+// NewTracerProvider is configured by the standard OTel environment
+// variables:
+//
+//   - OTEL_EXPORTER_OTLP_ENDPOINT: the OTLP/gRPC collector endpoint to
+//     export spans to. If unset, the OTLP exporter is omitted.
+//   - OTEL_EXPORTER_OTLP_HEADERS: comma-separated key=value headers to
+//     send with every OTLP export request, for example for an
+//     authentication token.
+//   - OTEL_SERVICE_NAME: the service.name resource attribute to report.
+//     Defaults to "terraform-stackruntime".
+//   - OTEL_TRACES_SAMPLER: one of "always_on", "always_off",
+//     "traceidratio", "parentbased_always_on", "parentbased_always_off",
+//     or "parentbased_traceidratio" (the "traceidratio" variants read
+//     their ratio from OTEL_TRACES_SAMPLER_ARG, as usual for OTel SDKs).
+//     Defaults to "parentbased_always_on".
+//
+// Config.EnableStdoutExporter additionally exports every span as
+// pretty-printed JSON to stderr, for local debugging of a real run. It
+// defaults to off and is not controlled by an environment variable, since
+// it's meant to be opted into deliberately for a single debugging session
+// rather than left on in a deployed environment.
+//
+// Whatever sampler this selects is then wrapped so that "promise" spans
+// nested deeper than Config.MaxPromiseDepth are dropped, while "async
+// task" root spans -- which anchor the rest of the promise graph -- are
+// always kept. See newPromiseDepthSampler for details.
+//
+// # Span and attribute schema
+//
+// The following is a stable surface that external tracing backends can
+// build dashboards and alerts against:
+//
+//   - Span "async task" is the root of one unit of concurrent work
+//     scheduled by the promising package. It's always kept by the default
+//     sampler, regardless of depth.
+//   - Span "promise" represents a single promise being awaited. These
+//     spans nest arbitrarily deeply as promises await other promises, and
+//     are the ones the depth-based sampler can drop.
+//   - Attributes "promise.waiting_for_id" and "promise.waiter_id" identify
+//     the span IDs (as 16 hex digit strings) on either side of a promise
+//     being awaited.
+//   - Attributes "promising.resolved_by", "promising.resolved_id",
+//     "promising.delegated_from", "promising.delegated_to", and
+//     "promising.responsible_for" identify span IDs involved in
+//     delegating responsibility for resolving a promise from one task to
+//     another.
+//   - Resource attributes "terraform.version", "terraform.stack_config_hash",
+//     and "terraform.operation" (one of "plan", "apply", or "validate")
+//     identify which Terraform build and which stack configuration
+//     produced a given trace.
+package tracing
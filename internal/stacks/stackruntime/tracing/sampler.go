@@ -0,0 +1,90 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultMaxPromiseDepth is, by default, the deepest nesting depth at which
+// "promise" spans are still sampled; deeper ones are dropped. It's
+// deliberately small: promise
+// chains in a large stack configuration can nest hundreds of levels deep,
+// and the spans below the first few levels rarely add information beyond
+// what's already visible from their ancestors and from the "async task"
+// root they ultimately belong to.
+const DefaultMaxPromiseDepth = 4
+
+type promiseDepthContextKey struct{}
+
+// ContextWithIncrementedPromiseDepth returns a new context derived from
+// ctx in which the promise nesting depth, as seen by the sampler returned
+// from newPromiseDepthSampler, is one greater than it was in ctx.
+//
+// The promising package should call this once for each "promise" span it
+// starts on behalf of another, already-started "promise" span, passing
+// the parent promise's context, before starting the child span. Spans
+// started from a context that was never passed through this function --
+// including every "async task" root span -- are treated as depth zero.
+func ContextWithIncrementedPromiseDepth(ctx context.Context) context.Context {
+	return context.WithValue(ctx, promiseDepthContextKey{}, promiseDepthFromContext(ctx)+1)
+}
+
+func promiseDepthFromContext(ctx context.Context) int {
+	depth, _ := ctx.Value(promiseDepthContextKey{}).(int)
+	return depth
+}
+
+// newPromiseDepthSampler wraps base so that "promise" spans nested deeper
+// than maxDepth are dropped and "async task" spans are always kept,
+// regardless of what base would otherwise decide; every other span is
+// left for base to decide on as usual.
+//
+// This keeps traces of a large stack plan/apply usable: the promise graph
+// can be extremely deep, but the spans nearest the root carry most of the
+// information a person debugging a real run needs, and the "async task"
+// roots are what stitch the kept spans back into a coherent picture of
+// concurrent work, so they need to survive even a restrictive base
+// sampler such as a low traceidratio.
+func newPromiseDepthSampler(base sdktrace.Sampler, maxDepth int) sdktrace.Sampler {
+	return &promiseDepthSampler{base: base, maxDepth: maxDepth}
+}
+
+type promiseDepthSampler struct {
+	base     sdktrace.Sampler
+	maxDepth int
+}
+
+var _ sdktrace.Sampler = (*promiseDepthSampler)(nil)
+
+// ShouldSample implements sdktrace.Sampler.
+func (s *promiseDepthSampler) ShouldSample(params sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if params.Name == "promise" && promiseDepthFromContext(params.ParentContext) > s.maxDepth {
+		return sdktrace.SamplingResult{
+			Decision:   sdktrace.Drop,
+			Tracestate: trace.SpanContextFromContext(params.ParentContext).TraceState(),
+		}
+	}
+	if params.Name == "async task" {
+		// "async task" roots are always kept, regardless of what base
+		// would otherwise decide, since they're what stitch the promise
+		// spans we do keep back into a coherent picture of concurrent
+		// work. A restrictive base sampler (e.g. a low traceidratio, or
+		// always_off) must not be able to drop them.
+		return sdktrace.SamplingResult{
+			Decision:   sdktrace.RecordAndSample,
+			Tracestate: trace.SpanContextFromContext(params.ParentContext).TraceState(),
+		}
+	}
+	return s.base.ShouldSample(params)
+}
+
+// Description implements sdktrace.Sampler.
+func (s *promiseDepthSampler) Description() string {
+	return fmt.Sprintf("PromiseDepthSampler{maxDepth=%d,base=%s}", s.maxDepth, s.base.Description())
+}
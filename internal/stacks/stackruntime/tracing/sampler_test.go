@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestPromiseDepthSampler(t *testing.T) {
+	ctxAtDepth := func(depth int) context.Context {
+		ctx := context.Background()
+		for i := 0; i < depth; i++ {
+			ctx = ContextWithIncrementedPromiseDepth(ctx)
+		}
+		return ctx
+	}
+
+	tests := map[string]struct {
+		base       sdktrace.Sampler
+		name       string
+		depth      int
+		wantSample bool
+	}{
+		"promise within max depth":                         {base: sdktrace.AlwaysSample(), name: "promise", depth: 2, wantSample: true},
+		"promise beyond max depth":                         {base: sdktrace.AlwaysSample(), name: "promise", depth: 3, wantSample: false},
+		"promise far beyond max":                           {base: sdktrace.AlwaysSample(), name: "promise", depth: 10, wantSample: false},
+		"async task at any depth":                          {base: sdktrace.AlwaysSample(), name: "async task", depth: 10, wantSample: true},
+		"other span at depth":                              {base: sdktrace.AlwaysSample(), name: "some other span", depth: 10, wantSample: true},
+		"promise at depth zero":                            {base: sdktrace.AlwaysSample(), name: "promise", depth: 0, wantSample: true},
+		"async task kept despite always_off base":          {base: sdktrace.NeverSample(), name: "async task", depth: 0, wantSample: true},
+		"promise within depth dropped by restrictive base": {base: sdktrace.NeverSample(), name: "promise", depth: 0, wantSample: false},
+		"other span dropped by restrictive base":           {base: sdktrace.NeverSample(), name: "some other span", depth: 0, wantSample: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sampler := newPromiseDepthSampler(test.base, 2)
+			result := sampler.ShouldSample(sdktrace.SamplingParameters{
+				ParentContext: ctxAtDepth(test.depth),
+				Name:          test.name,
+			})
+			got := result.Decision != sdktrace.Drop
+			if got != test.wantSample {
+				t.Errorf("ShouldSample(name=%q, depth=%d) sampled = %v, want %v", test.name, test.depth, got, test.wantSample)
+			}
+		})
+	}
+}
+
+func TestContextWithIncrementedPromiseDepth(t *testing.T) {
+	ctx := context.Background()
+	if got, want := promiseDepthFromContext(ctx), 0; got != want {
+		t.Fatalf("depth of a fresh context = %d, want %d", got, want)
+	}
+
+	ctx = ContextWithIncrementedPromiseDepth(ctx)
+	ctx = ContextWithIncrementedPromiseDepth(ctx)
+	if got, want := promiseDepthFromContext(ctx), 2; got != want {
+		t.Fatalf("depth after two increments = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// Resource attribute keys specific to stackruntime traces. These, along
+// with the promise.* and promising.* span attributes documented in the
+// package doc comment, are a stable surface: dashboards and alerts in an
+// external tracing backend can be built against them.
+const (
+	terraformVersionKey         = attribute.Key("terraform.version")
+	terraformStackConfigHashKey = attribute.Key("terraform.stack_config_hash")
+	terraformOperationKey       = attribute.Key("terraform.operation")
+)
+
+// Config describes the caller-supplied settings for NewTracerProvider, in
+// addition to whatever it reads from the standard OTel environment
+// variables: the details it attaches to every span as resource attributes,
+// and the promise-depth sampling and stdout exporter options.
+type Config struct {
+	// TerraformVersion is the version of Terraform CLI (or Terraform
+	// Cloud/Enterprise agent) producing the trace.
+	TerraformVersion string
+
+	// StackConfigHash identifies the stack configuration being evaluated,
+	// so that traces from different configurations (or different versions
+	// of the same configuration) can be told apart in a backend that
+	// aggregates traces across many runs.
+	StackConfigHash string
+
+	// Operation is the kind of evaluation producing the trace: "plan",
+	// "apply", or "validate".
+	Operation string
+
+	// MaxPromiseDepth overrides DefaultMaxPromiseDepth, the deepest
+	// nesting depth at which "promise" spans are still sampled; deeper
+	// ones are dropped. Zero means use the default.
+	MaxPromiseDepth int
+
+	// EnableStdoutExporter additionally exports every span as
+	// pretty-printed JSON to stderr, which is useful for local debugging
+	// of a real run.
+	//
+	// It defaults to off, and even when enabled writes to stderr rather
+	// than stdout: Terraform CLI's stdout is sometimes a structured,
+	// scriptable output channel (e.g. `terraform plan -json`), and
+	// unconditionally interleaving trace JSON into it would corrupt that
+	// stream.
+	EnableStdoutExporter bool
+}
+
+// NewTracerProvider constructs the production TracerProvider that
+// stackruntime should use to trace a real plan, apply, or validate
+// operation.
+//
+// It reads its exporter and sampler configuration from the standard OTel
+// environment variables (see the package doc comment), and attaches a
+// Resource built from cfg identifying the Terraform version, stack
+// configuration, and operation responsible for the resulting traces. The
+// OTLP exporter is included only if OTEL_EXPORTER_OTLP_ENDPOINT is set;
+// the stdout exporter is included only if cfg.EnableStdoutExporter is
+// true. If neither applies, the returned TracerProvider still samples
+// and tracks spans, it just has nowhere to send them.
+//
+// The caller is responsible for calling Shutdown on the returned
+// TracerProvider once the operation it's tracing has finished, so that
+// any buffered spans are flushed to the configured exporters.
+func NewTracerProvider(ctx context.Context, cfg Config) (*sdktrace.TracerProvider, error) {
+	res, err := newResource(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("building trace resource: %w", err)
+	}
+
+	sampler, err := samplerFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("configuring trace sampler: %w", err)
+	}
+	maxDepth := cfg.MaxPromiseDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxPromiseDepth
+	}
+	sampler = newPromiseDepthSampler(sampler, maxDepth)
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sampler),
+	}
+
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		otlpExp, err := newOTLPExporter(ctx, endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("configuring OTLP exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(otlpExp))
+	}
+
+	if cfg.EnableStdoutExporter {
+		stdoutExp, err := stdouttrace.New(stdouttrace.WithPrettyPrint(), stdouttrace.WithWriter(os.Stderr))
+		if err != nil {
+			return nil, fmt.Errorf("configuring stdout exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(stdoutExp))
+	}
+
+	return sdktrace.NewTracerProvider(opts...), nil
+}
+
+func newResource(ctx context.Context, cfg Config) (*resource.Resource, error) {
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = "terraform-stackruntime"
+	}
+
+	return resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceNameKey.String(serviceName),
+			terraformVersionKey.String(cfg.TerraformVersion),
+			terraformStackConfigHashKey.String(cfg.StackConfigHash),
+			terraformOperationKey.String(cfg.Operation),
+		),
+	)
+}
+
+func newOTLPExporter(ctx context.Context, endpoint string) (sdktrace.SpanExporter, error) {
+	opts := []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(endpoint),
+	}
+	if headers := os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"); headers != "" {
+		parsed, err := parseHeaders(headers)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, otlptracegrpc.WithHeaders(parsed))
+	}
+	return otlptracegrpc.New(ctx, opts...)
+}
+
+// parseHeaders parses the comma-separated key=value list used by
+// OTEL_EXPORTER_OTLP_HEADERS, per the OpenTelemetry environment variable
+// specification.
+func parseHeaders(raw string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid entry %q in OTEL_EXPORTER_OTLP_HEADERS", pair)
+		}
+		headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return headers, nil
+}
+
+// samplerFromEnv implements the subset of OTEL_TRACES_SAMPLER that's
+// meaningful for a single stackruntime evaluation: the "always_on",
+// "always_off", and "traceidratio" samplers and their "parentbased_"
+// variants. An unset value falls back to parentbased_always_on, matching
+// the OTel SDK's own default; an unrecognized value is an error.
+func samplerFromEnv() (sdktrace.Sampler, error) {
+	name := os.Getenv("OTEL_TRACES_SAMPLER")
+	switch name {
+	case "", "parentbased_always_on":
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case "always_on":
+		return sdktrace.AlwaysSample(), nil
+	case "always_off":
+		return sdktrace.NeverSample(), nil
+	case "parentbased_always_off":
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case "traceidratio", "parentbased_traceidratio":
+		ratio, err := strconv.ParseFloat(os.Getenv("OTEL_TRACES_SAMPLER_ARG"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OTEL_TRACES_SAMPLER_ARG for sampler %q: %w", name, err)
+		}
+		base := sdktrace.TraceIDRatioBased(ratio)
+		if name == "parentbased_traceidratio" {
+			return sdktrace.ParentBased(base), nil
+		}
+		return base, nil
+	default:
+		return nil, fmt.Errorf("unsupported OTEL_TRACES_SAMPLER %q", name)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package tracing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSamplerFromEnv(t *testing.T) {
+	tests := map[string]struct {
+		sampler   string
+		arg       string
+		wantDescr string
+		wantErr   string
+	}{
+		"unset": {
+			sampler:   "",
+			wantDescr: "ParentBased{root:AlwaysOnSampler,remoteParentSampled:AlwaysOnSampler,remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,localParentNotSampled:AlwaysOffSampler}",
+		},
+		"parentbased_always_on": {
+			sampler:   "parentbased_always_on",
+			wantDescr: "ParentBased{root:AlwaysOnSampler,remoteParentSampled:AlwaysOnSampler,remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,localParentNotSampled:AlwaysOffSampler}",
+		},
+		"always_on": {
+			sampler:   "always_on",
+			wantDescr: "AlwaysOnSampler",
+		},
+		"always_off": {
+			sampler:   "always_off",
+			wantDescr: "AlwaysOffSampler",
+		},
+		"parentbased_always_off": {
+			sampler:   "parentbased_always_off",
+			wantDescr: "ParentBased{root:AlwaysOffSampler,remoteParentSampled:AlwaysOnSampler,remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,localParentNotSampled:AlwaysOffSampler}",
+		},
+		"traceidratio": {
+			sampler:   "traceidratio",
+			arg:       "0.5",
+			wantDescr: "TraceIDRatioBased{0.5}",
+		},
+		"parentbased_traceidratio": {
+			sampler:   "parentbased_traceidratio",
+			arg:       "0.25",
+			wantDescr: "ParentBased{root:TraceIDRatioBased{0.25},remoteParentSampled:AlwaysOnSampler,remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,localParentNotSampled:AlwaysOffSampler}",
+		},
+		"traceidratio with invalid arg": {
+			sampler: "traceidratio",
+			arg:     "not-a-number",
+			wantErr: "invalid OTEL_TRACES_SAMPLER_ARG",
+		},
+		"unsupported sampler name": {
+			sampler: "nonsense",
+			wantErr: "unsupported OTEL_TRACES_SAMPLER",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_SAMPLER", test.sampler)
+			t.Setenv("OTEL_TRACES_SAMPLER_ARG", test.arg)
+
+			got, err := samplerFromEnv()
+			if test.wantErr != "" {
+				if err == nil {
+					t.Fatalf("got no error, want one containing %q", test.wantErr)
+				}
+				if !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("error %q does not contain %q", err.Error(), test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got, want := got.Description(), test.wantDescr; got != want {
+				t.Errorf("wrong sampler: got %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	tests := map[string]struct {
+		raw     string
+		want    map[string]string
+		wantErr bool
+	}{
+		"empty":       {raw: "", want: map[string]string{}},
+		"single":      {raw: "api-key=abc123", want: map[string]string{"api-key": "abc123"}},
+		"multiple":    {raw: "a=1,b=2", want: map[string]string{"a": "1", "b": "2"}},
+		"with spaces": {raw: " a = 1 , b = 2 ", want: map[string]string{"a": "1", "b": "2"}},
+		"missing '='": {raw: "a=1,bogus", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseHeaders(test.raw)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("got no error, want one")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("got %d headers, want %d", len(got), len(test.want))
+			}
+			for k, v := range test.want {
+				if got[k] != v {
+					t.Errorf("header %q = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}